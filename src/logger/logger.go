@@ -1,20 +1,14 @@
 package logger
 
 import (
+	"context"
 	"runtime"
 	"strings"
 	"time"
 
-	zerologpkg "github.com/rs/zerolog"
-	zerolog "github.com/rs/zerolog/log"
-	"github.com/rs/zerolog/pkgerrors"
+	"kata/src/tracing"
 )
 
-func init() {
-	zerologpkg.TimeFieldFormat = zerologpkg.TimeFormatUnix
-	zerologpkg.ErrorStackMarshaler = pkgerrors.MarshalStack
-}
-
 type Tracer func()
 
 type T interface {
@@ -23,6 +17,7 @@ type T interface {
 	Error(string)
 	Debug(string)
 	Prefix(string)
+	With(key string, value interface{}) T
 }
 
 type Event struct {
@@ -43,15 +38,105 @@ var Events = struct {
 	Debug:      Event{"debug"},
 }
 
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+)
+
+// Level classifies event for min-level filtering; trace start/end count as info.
+func (event Event) Level() Level {
+	switch event {
+	case Events.Debug:
+		return LevelDebug
+	case Events.Error:
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// Fields are the structured key/values attached to a single log event.
+type Fields map[string]interface{}
+
+func (fields Fields) clone() Fields {
+	out := make(Fields, len(fields))
+	for k, v := range fields {
+		out[k] = v
+	}
+	return out
+}
+
 type EventHandler func(event Event, message string)
 
+// SpanExporter ships spans recorded by Trace to a tracing backend.
+type SpanExporter = tracing.SpanExporter
+
+var spanExporter SpanExporter
+
+// SetSpanExporter registers the exporter spans are handed to once Trace
+// closes them. A nil exporter (the default) discards spans.
+func SetSpanExporter(exporter SpanExporter) {
+	spanExporter = exporter
+}
+
+// WithSpan returns a context carrying a new span: a child of whatever span
+// ctx already holds, or a fresh root span if it holds none. Handlers use
+// this to start a trace before calling into reqwest or Trace.
+func WithSpan(ctx context.Context) context.Context {
+	return tracing.ContextWithSpan(ctx, tracing.NewSpan(ctx))
+}
+
 type logger struct {
-	prefix     string
-	beforeEach []EventHandler
+	ctx          context.Context
+	prefix       string
+	span         tracing.Span
+	beforeEach   []EventHandler
+	sink         Sink
+	minLevel     Level
+	staticFields Fields
+	sampler      func(Event) bool
+	fields       Fields
+}
+
+// Option configures a logger built with New.
+type Option func(*logger)
+
+// WithSink sets where events are written. Defaults to StdoutSink{}, the
+// original zerolog-to-stdout behavior.
+func WithSink(sink Sink) Option {
+	return func(log *logger) { log.sink = sink }
+}
+
+// WithMinLevel drops events below level before they reach the sink.
+func WithMinLevel(level Level) Option {
+	return func(log *logger) { log.minLevel = level }
+}
+
+// WithStaticFields attaches fields to every event this logger emits.
+func WithStaticFields(fields Fields) Option {
+	return func(log *logger) { log.staticFields = fields.clone() }
 }
 
-func New() logger {
-	return logger{}
+// WithSampler lets noisy events (typically Debug/Trace) be dropped
+// probabilistically; sampler returning false drops the event.
+func WithSampler(sampler func(Event) bool) Option {
+	return func(log *logger) { log.sampler = sampler }
+}
+
+func New(opts ...Option) logger {
+	log := logger{
+		ctx:  context.Background(),
+		sink: StdoutSink{},
+	}
+
+	for _, opt := range opts {
+		opt(&log)
+	}
+
+	return log
 }
 
 func caller(skip int) string {
@@ -77,42 +162,126 @@ func (log *logger) callBeforeEachHandlers(event Event, message string) {
 	}
 }
 
+// Context binds log to ctx: the sink receives it with every event, and the
+// logger adopts whatever span ctx carries (tracing.ContextWithSpan) so
+// trace_id/span_id line up with the request ctx belongs to.
+func (log *logger) Context(ctx context.Context) T {
+	out := *log
+	out.ctx = ctx
+
+	if span, ok := tracing.SpanFromContext(ctx); ok {
+		out.span = span
+	}
+
+	return &out
+}
+
+// With returns a logger that additionally attaches key/value to every event
+// it emits from here on, without affecting the receiver.
+func (log *logger) With(key string, value interface{}) T {
+	out := *log
+	out.fields = log.fields.clone()
+	out.fields[key] = value
+	return &out
+}
+
+func (log *logger) fieldsFor(message string) Fields {
+	fields := make(Fields, len(log.staticFields)+len(log.fields)+4)
+
+	for k, v := range log.staticFields {
+		fields[k] = v
+	}
+	for k, v := range log.fields {
+		fields[k] = v
+	}
+
+	fields["correlation_id"] = log.prefix
+	fields["trace_id"] = log.span.TraceID
+	fields["span_id"] = log.span.SpanID
+	fields["message"] = message
+
+	return fields
+}
+
+func (log *logger) write(event Event, fields Fields) {
+	if event.Level() < log.minLevel {
+		return
+	}
+
+	if log.sampler != nil && !log.sampler(event) {
+		return
+	}
+
+	sink := log.sink
+	if sink == nil {
+		sink = StdoutSink{}
+	}
+
+	ctx := log.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sink.Write(ctx, event, fields)
+}
+
 func (log *logger) Trace(message string) Tracer {
 	log.callBeforeEachHandlers(Events.TraceStart, message)
 
+	span := log.span.NewChild()
+	log.span = span
+
 	start := time.Now()
 
-	zerolog.Info().
-		Caller(1).
-		Str("correlation_id", log.prefix).
-		Msgf("[END - %d(%dms)] %s", time.Now().UnixMilli(), time.Since(start).Milliseconds(), message)
+	startFields := log.fieldsFor(message)
+	startFields["caller"] = caller(3)
+	startFields["phase"] = "start"
+	log.write(Events.TraceStart, startFields)
 
 	return func() {
 		log.callBeforeEachHandlers(Events.TraceEnd, message)
 
-		zerolog.Info().
-			Caller(1).
-			Str("correlation_id", log.prefix).
-			Msgf("[END - %d(%dms)] %s", time.Now().UnixMilli(), time.Since(start).Milliseconds(), message)
+		end := time.Now()
+
+		endFields := log.fieldsFor(message)
+		endFields["caller"] = caller(3)
+		endFields["phase"] = "end"
+		endFields["duration_ms"] = end.Sub(start).Milliseconds()
+		log.write(Events.TraceEnd, endFields)
+
+		if spanExporter != nil {
+			spanExporter.Export(tracing.FinishedSpan{
+				Span:      span,
+				Name:      message,
+				StartTime: start,
+				EndTime:   end,
+			})
+		}
 	}
 }
 
 func (log *logger) Info(message string) {
 	log.callBeforeEachHandlers(Events.Info, message)
 
-	zerolog.Info().Caller(1).Str("correlation_id", log.prefix).Msg(message)
+	fields := log.fieldsFor(message)
+	fields["caller"] = caller(3)
+	log.write(Events.Info, fields)
 }
 
 func (log *logger) Error(message string) {
 	log.callBeforeEachHandlers(Events.Error, message)
 
-	zerolog.Error().Stack().Str("correlation_id", log.prefix).Msg(message)
+	fields := log.fieldsFor(message)
+	fields["caller"] = caller(3)
+	log.write(Events.Error, fields)
 }
 
 func (log *logger) Debug(message string) {
 	log.callBeforeEachHandlers(Events.Debug, message)
 
-	zerolog.Debug().Caller(1).Str("correlation_id", log.prefix).Msg(message)
+	fields := log.fieldsFor(message)
+	fields["caller"] = caller(3)
+	log.write(Events.Debug, fields)
 }
 
 func (log *logger) Prefix(prefix string) {
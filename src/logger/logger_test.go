@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kata/src/tracing"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+	fields []Fields
+}
+
+func (sink *recordingSink) Write(_ context.Context, event Event, fields Fields) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.events = append(sink.events, event)
+	sink.fields = append(sink.fields, fields)
+}
+
+func (sink *recordingSink) last() Fields {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return sink.fields[len(sink.fields)-1]
+}
+
+func Test_Logger_Info(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes correlation id and message to the sink", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		log := New(WithSink(sink))
+		log.Prefix("some-correlation-id")
+
+		log.Info("hello")
+
+		fields := sink.last()
+
+		assert.Equal(t, "some-correlation-id", fields["correlation_id"])
+		assert.Equal(t, "hello", fields["message"])
+	})
+}
+
+func Test_Logger_With(t *testing.T) {
+	t.Parallel()
+
+	t.Run("attaches custom fields without mutating the receiver", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		log := New(WithSink(sink))
+
+		log.With("user_id", "123").Info("hello")
+		log.Info("hello again")
+
+		assert.Equal(t, "123", sink.fields[0]["user_id"])
+		assert.Nil(t, sink.fields[1]["user_id"])
+	})
+}
+
+func Test_Logger_Context(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adopts the span carried by ctx without mutating the receiver", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		span := tracing.Span{TraceID: "trace-id", SpanID: "span-id"}
+		ctx := tracing.ContextWithSpan(context.Background(), span)
+
+		log := New(WithSink(sink))
+
+		log.Context(ctx).Info("hello")
+		log.Info("hello again")
+
+		assert.Equal(t, "trace-id", sink.fields[0]["trace_id"])
+		assert.Equal(t, "span-id", sink.fields[0]["span_id"])
+		assert.Equal(t, "", sink.fields[1]["trace_id"])
+	})
+}
+
+func Test_Logger_WithMinLevel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drops events below the configured level", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		log := New(WithSink(sink), WithMinLevel(LevelInfo))
+
+		log.Debug("should be dropped")
+		log.Info("should go through")
+
+		assert.Len(t, sink.events, 1)
+		assert.Equal(t, Events.Info, sink.events[0])
+	})
+}
+
+func Test_Logger_WithSampler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drops events the sampler rejects", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		log := New(WithSink(sink), WithSampler(func(Event) bool { return false }))
+
+		log.Info("hello")
+
+		assert.Empty(t, sink.events)
+	})
+}
+
+func Test_Logger_WithStaticFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("attaches the same fields to every event", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingSink{}
+
+		log := New(WithSink(sink), WithStaticFields(Fields{"service": "kata"}))
+
+		log.Info("hello")
+
+		assert.Equal(t, "kata", sink.last()["service"])
+	})
+}
+
+func Test_FanOutSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes to every sink", func(t *testing.T) {
+		t.Parallel()
+
+		first := &recordingSink{}
+		second := &recordingSink{}
+
+		log := New(WithSink(FanOutSink{Sinks: []Sink{first, second}}))
+
+		log.Info("hello")
+
+		assert.Len(t, first.events, 1)
+		assert.Len(t, second.events, 1)
+	})
+}
+
+func Test_AsyncSink_dropsOldestWhenFull(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drops the oldest queued event instead of blocking", func(t *testing.T) {
+		t.Parallel()
+
+		// Built directly (not via NewAsyncSink) so no background consumer
+		// drains the queue, keeping the backpressure scenario deterministic.
+		sink := &AsyncSink{queue: make(chan batchedEvent, 1)}
+
+		for i := 0; i < 10; i++ {
+			sink.Write(context.Background(), Events.Info, Fields{"i": i})
+		}
+
+		assert.Equal(t, uint64(9), sink.Dropped())
+	})
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	zerologpkg "github.com/rs/zerolog"
+	zerolog "github.com/rs/zerolog/log"
+	"github.com/rs/zerolog/pkgerrors"
+)
+
+func init() {
+	zerologpkg.TimeFieldFormat = zerologpkg.TimeFormatUnix
+	zerologpkg.ErrorStackMarshaler = pkgerrors.MarshalStack
+}
+
+// Sink receives every event a logger emits, after level filtering and
+// sampling have already run. Write must not panic; sinks that talk to the
+// network should not block the caller for long (see AsyncSink).
+type Sink interface {
+	Write(ctx context.Context, event Event, fields Fields)
+}
+
+// StdoutSink writes events as JSON to stdout via zerolog. This is the
+// logger's original (and default) behavior.
+type StdoutSink struct{}
+
+func (StdoutSink) Write(_ context.Context, event Event, fields Fields) {
+	var entry *zerologpkg.Event
+
+	switch event.Level() {
+	case LevelError:
+		entry = zerolog.Error().Stack()
+	case LevelDebug:
+		entry = zerolog.Debug()
+	default:
+		entry = zerolog.Info()
+	}
+
+	message, _ := fields["message"].(string)
+
+	for key, value := range fields {
+		if key == "message" {
+			continue
+		}
+		entry = entry.Interface(key, value)
+	}
+
+	entry.Msg(message)
+}
+
+// FanOutSink dispatches every event to each of Sinks in order.
+type FanOutSink struct {
+	Sinks []Sink
+}
+
+func (fanOut FanOutSink) Write(ctx context.Context, event Event, fields Fields) {
+	for _, sink := range fanOut.Sinks {
+		sink.Write(ctx, event, fields)
+	}
+}
+
+type batchedEvent struct {
+	Event  string `json:"event"`
+	Fields Fields `json:"fields"`
+}
+
+// AsyncSink buffers events to a channel and flushes batches to an HTTP
+// endpoint (Loki/Elasticsearch/OTLP-logs accept newline- or JSON-array
+// shaped batches like the one posted here). When the buffer is full, the
+// oldest queued event is dropped to make room rather than blocking the
+// caller; Dropped reports how many events were lost this way.
+type AsyncSink struct {
+	endpoint   string
+	client     *http.Client
+	queue      chan batchedEvent
+	batchSize  int
+	flushEvery time.Duration
+	dropped    uint64
+}
+
+func NewAsyncSink(endpoint string, queueSize, batchSize int, flushEvery time.Duration) *AsyncSink {
+	sink := &AsyncSink{
+		endpoint:   endpoint,
+		client:     http.DefaultClient,
+		queue:      make(chan batchedEvent, queueSize),
+		batchSize:  batchSize,
+		flushEvery: flushEvery,
+	}
+
+	go sink.run()
+
+	return sink
+}
+
+func (sink *AsyncSink) Write(_ context.Context, event Event, fields Fields) {
+	entry := batchedEvent{Event: event.name, Fields: fields}
+
+	select {
+	case sink.queue <- entry:
+		return
+	default:
+	}
+
+	// Queue is full: drop the oldest entry to make room for this one
+	// instead of blocking the caller.
+	select {
+	case <-sink.queue:
+		atomic.AddUint64(&sink.dropped, 1)
+	default:
+	}
+
+	select {
+	case sink.queue <- entry:
+	default:
+		atomic.AddUint64(&sink.dropped, 1)
+	}
+}
+
+// Dropped returns how many events have been discarded due to backpressure.
+func (sink *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&sink.dropped)
+}
+
+func (sink *AsyncSink) run() {
+	batch := make([]batchedEvent, 0, sink.batchSize)
+
+	ticker := time.NewTicker(sink.flushEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case entry, ok := <-sink.queue:
+			if !ok {
+				sink.flush(batch)
+				return
+			}
+
+			batch = append(batch, entry)
+
+			if len(batch) >= sink.batchSize {
+				sink.flush(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				sink.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (sink *AsyncSink) flush(batch []batchedEvent) {
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return
+	}
+
+	response, err := sink.client.Post(sink.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	response.Body.Close()
+}
+
+// Close stops accepting new events and flushes whatever is left in the
+// queue. Callers must not call Write after Close.
+func (sink *AsyncSink) Close() {
+	close(sink.queue)
+}
@@ -0,0 +1,165 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker in place of calling next
+// while a host's circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open for this host")
+
+// CircuitBreakerPolicy controls when a per-host circuit trips open and when
+// it is given another chance. A circuit trips after ConsecutiveFailures in
+// a row, or once more than FailureRatio of the last WindowSize outcomes
+// failed (whichever happens first). Once Cooldown elapses it moves to half-open
+// and lets a single probe request through; that probe's outcome decides
+// whether the circuit closes again or reopens.
+type CircuitBreakerPolicy struct {
+	ConsecutiveFailures int
+	WindowSize          int
+	FailureRatio        float64
+	Cooldown            time.Duration
+}
+
+type hostCircuit struct {
+	mu                  sync.Mutex
+	state               circuitState
+	consecutiveFailures int
+	outcomes            []bool // true = success, oldest first
+	openedAt            time.Time
+}
+
+// CircuitBreaker is a per-host circuit breaker middleware: closed → open
+// after ConsecutiveFailures in a row or a failure ratio above threshold
+// over the rolling window; open → half-open after Cooldown; half-open →
+// closed on the probe's success, or back to open on its failure.
+type CircuitBreaker struct {
+	policy   CircuitBreakerPolicy
+	mu       sync.Mutex
+	circuits map[string]*hostCircuit
+}
+
+func NewCircuitBreaker(policy CircuitBreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		policy:   policy,
+		circuits: make(map[string]*hostCircuit),
+	}
+}
+
+func (breaker *CircuitBreaker) circuitFor(host string) *hostCircuit {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	circuit, ok := breaker.circuits[host]
+	if !ok {
+		circuit = &hostCircuit{}
+		breaker.circuits[host] = circuit
+	}
+
+	return circuit
+}
+
+// Middleware returns the breaker as a reqwest Middleware, so it can be
+// installed with reqwest.Use or builder.Use.
+func (breaker *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return func(ctx context.Context, request *http.Request) (*Response, error) {
+			circuit := breaker.circuitFor(request.URL.Hostname())
+
+			allowed, probing := circuit.allow(breaker.policy)
+			if !allowed {
+				return nil, errors.WithStack(ErrCircuitOpen)
+			}
+
+			response, err := next(ctx, request)
+
+			circuit.record(breaker.policy, err == nil, probing)
+
+			return response, err
+		}
+	}
+}
+
+// allow reports whether a request may proceed, and whether it is the
+// single probe attempt of a half-open circuit.
+func (circuit *hostCircuit) allow(policy CircuitBreakerPolicy) (allowed, probing bool) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+
+	switch circuit.state {
+	case circuitOpen:
+		if time.Since(circuit.openedAt) < policy.Cooldown {
+			return false, false
+		}
+
+		circuit.state = circuitHalfOpen
+		return true, true
+	case circuitHalfOpen:
+		return false, false
+	default:
+		return true, false
+	}
+}
+
+func (circuit *hostCircuit) record(policy CircuitBreakerPolicy, success, probing bool) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+
+	if probing {
+		if success {
+			circuit.state = circuitClosed
+			circuit.consecutiveFailures = 0
+			circuit.outcomes = circuit.outcomes[:0]
+		} else {
+			circuit.state = circuitOpen
+			circuit.openedAt = time.Now()
+		}
+
+		return
+	}
+
+	circuit.outcomes = append(circuit.outcomes, success)
+	if len(circuit.outcomes) > policy.WindowSize {
+		circuit.outcomes = circuit.outcomes[1:]
+	}
+
+	if success {
+		circuit.consecutiveFailures = 0
+		return
+	}
+
+	circuit.consecutiveFailures++
+
+	if circuit.consecutiveFailures >= policy.ConsecutiveFailures || circuit.failureRatio() > policy.FailureRatio {
+		circuit.state = circuitOpen
+		circuit.openedAt = time.Now()
+	}
+}
+
+func (circuit *hostCircuit) failureRatio() float64 {
+	if len(circuit.outcomes) == 0 {
+		return 0
+	}
+
+	failures := 0
+	for _, success := range circuit.outcomes {
+		if !success {
+			failures++
+		}
+	}
+
+	return float64(failures) / float64(len(circuit.outcomes))
+}
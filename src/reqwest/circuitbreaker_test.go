@@ -0,0 +1,133 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequest(t *testing.T) *http.Request {
+	t.Helper()
+
+	request, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	assert.NoError(t, err)
+
+	return request
+}
+
+func Test_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trips open after ConsecutiveFailures in a row", func(t *testing.T) {
+		t.Parallel()
+
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{ConsecutiveFailures: 2, WindowSize: 10, FailureRatio: 1, Cooldown: time.Minute})
+
+		failing := func(ctx context.Context, request *http.Request) (*Response, error) {
+			return nil, assert.AnError
+		}
+
+		roundTrip := breaker.Middleware()(failing)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("trips open once the failure ratio over the window is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{ConsecutiveFailures: 100, WindowSize: 4, FailureRatio: 0.5, Cooldown: time.Minute})
+
+		outcomes := []bool{true, false, false}
+		call := 0
+
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			success := outcomes[call]
+			call++
+
+			if !success {
+				return nil, assert.AnError
+			}
+
+			return &Response{}, nil
+		}
+
+		roundTrip := breaker.Middleware()(next)
+		request := newRequest(t)
+
+		for range outcomes {
+			_, _ = roundTrip(context.Background(), request)
+		}
+
+		_, err := roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("lets a single probe through once Cooldown elapses, closing the circuit on success", func(t *testing.T) {
+		t.Parallel()
+
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{ConsecutiveFailures: 1, WindowSize: 10, FailureRatio: 1, Cooldown: time.Millisecond})
+
+		succeed := false
+
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			if succeed {
+				return &Response{}, nil
+			}
+
+			return nil, assert.AnError
+		}
+
+		roundTrip := breaker.Middleware()(next)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		time.Sleep(2 * time.Millisecond)
+		succeed = true
+
+		_, err = roundTrip(context.Background(), request)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reopens on a failed probe", func(t *testing.T) {
+		t.Parallel()
+
+		breaker := NewCircuitBreaker(CircuitBreakerPolicy{ConsecutiveFailures: 1, WindowSize: 10, FailureRatio: 1, Cooldown: time.Millisecond})
+
+		failing := func(ctx context.Context, request *http.Request) (*Response, error) {
+			return nil, assert.AnError
+		}
+
+		roundTrip := breaker.Middleware()(failing)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		time.Sleep(2 * time.Millisecond)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+}
@@ -0,0 +1,96 @@
+package reqwest
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HedgePolicy fires a duplicate request After elapses without a response,
+// up to MaxExtra times, and returns whichever response lands first.
+type HedgePolicy struct {
+	After    time.Duration
+	MaxExtra int
+}
+
+// Hedge attaches a hedge policy to the request. Idempotent methods
+// (GET/PUT/DELETE/HEAD/OPTIONS) hedge as configured; POST/PATCH only hedge
+// when HedgeSafe was called.
+func (builder *RequestBuilder) Hedge(policy HedgePolicy) *RequestBuilder {
+	builder.hedgePolicy = &policy
+
+	return builder
+}
+
+// HedgeSafe opts a non-idempotent request (POST/PATCH) into the hedge
+// policy set via Hedge.
+func (builder *RequestBuilder) HedgeSafe() *RequestBuilder {
+	builder.hedgeSafe = true
+
+	return builder
+}
+
+func (builder *RequestBuilder) isHedgeable() bool {
+	return defaultIdempotentMethod(builder.method) || builder.hedgeSafe
+}
+
+// sendWithHedge races the original attempt against up to MaxExtra duplicates
+// fired every After without a response, returning whichever completes
+// first. Every attempt shares builder's context, so canceling it once a
+// winner is picked aborts the rest; each attempt still drains and closes
+// its own response body in makeRequest regardless of whether it wins, so no
+// abandoned response leaks a connection.
+func (builder *RequestBuilder) sendWithHedge() (Response, error) {
+	builder.normalizeDeadline()
+	builder.ensureSpan()
+
+	policy := builder.hedgePolicy
+
+	ctx, cancel := context.WithCancel(builder.ctx)
+	defer cancel()
+
+	results := make(chan *ResponseBuilder, policy.MaxExtra+1)
+
+	fire := func() {
+		attempt := *builder
+		attempt.ctx = ctx
+		attempt.hedgePolicy = nil
+
+		if builder.getBody != nil {
+			attempt.body = builder.getBody()
+		}
+
+		responseBuilder := attempt.Build()
+		responseBuilder.makeRequest()
+
+		results <- responseBuilder
+	}
+
+	go fire()
+
+	timer := time.NewTimer(policy.After)
+	defer timer.Stop()
+
+	extraFired := 0
+
+	for {
+		select {
+		case responseBuilder := <-results:
+			cancel()
+
+			if responseBuilder.err != nil {
+				return responseBuilder.response, errors.WithStack(responseBuilder.err)
+			}
+
+			return responseBuilder.response, nil
+
+		case <-timer.C:
+			if extraFired < policy.MaxExtra {
+				extraFired++
+				go fire()
+				timer.Reset(policy.After)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RequestDuration is a registered histogram to pass to MetricsMiddleware,
+// labeled by host, method and status so a slow/failing host stands out in
+// aggregate without needing per-request logs.
+var RequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "reqwest_request_duration_seconds",
+		Help:    "Duration of outgoing HTTP requests made through reqwest, by host, method and status.",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"host", "method", "status"},
+)
+
+func init() {
+	prometheus.MustRegister(RequestDuration)
+}
+
+// MetricsMiddleware records request duration on observer, labeled by host,
+// method and status. A failed attempt with no response is recorded under
+// status "error".
+func MetricsMiddleware(observer *prometheus.HistogramVec) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return func(ctx context.Context, request *http.Request) (*Response, error) {
+			start := time.Now()
+
+			response, err := next(ctx, request)
+
+			status := "error"
+			if response != nil && response.Response != nil {
+				status = strconv.Itoa(response.StatusCode)
+			}
+
+			observer.WithLabelValues(request.URL.Hostname(), request.Method, status).
+				Observe(time.Since(start).Seconds())
+
+			return response, err
+		}
+	}
+}
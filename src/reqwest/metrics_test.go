@@ -0,0 +1,60 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func histogramSampleCount(t *testing.T, observer *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+
+	metric := &dto.Metric{}
+	assert.NoError(t, observer.WithLabelValues(labels...).(prometheus.Histogram).Write(metric))
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func Test_MetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records duration labeled by host, method and status on success", func(t *testing.T) {
+		t.Parallel()
+
+		observer := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_success_duration_seconds"}, []string{"host", "method", "status"})
+
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			return &Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+		}
+
+		roundTrip := MetricsMiddleware(observer)(next)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), histogramSampleCount(t, observer, "example.com", http.MethodGet, "200"))
+	})
+
+	t.Run("labels a failed attempt with no response as status error", func(t *testing.T) {
+		t.Parallel()
+
+		observer := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_error_duration_seconds"}, []string{"host", "method", "status"})
+
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			return nil, assert.AnError
+		}
+
+		roundTrip := MetricsMiddleware(observer)(next)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, uint64(1), histogramSampleCount(t, observer, "example.com", http.MethodGet, "error"))
+	})
+}
@@ -0,0 +1,69 @@
+package reqwest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// RoundTripper executes (or forwards) a single HTTP request attempt.
+type RoundTripper func(ctx context.Context, request *http.Request) (*Response, error)
+
+// Middleware wraps a RoundTripper with behavior that can run before and/or
+// after the request: auth, metrics, logging, circuit breaking, retries, etc.
+// A middleware that returns without calling next short-circuits the chain.
+type Middleware func(next RoundTripper) RoundTripper
+
+var middlewares []Middleware
+
+// Use registers middleware that every request goes through, ahead of
+// whatever a builder registers with its own Use.
+func Use(mw ...Middleware) {
+	middlewares = append(middlewares, mw...)
+}
+
+func (builder *RequestBuilder) Use(mw ...Middleware) *RequestBuilder {
+	builder.middlewares = append(builder.middlewares, mw...)
+
+	return builder
+}
+
+// chain composes the global middlewares, then the builder's own, around the
+// terminal round tripper that actually performs the HTTP call. The first
+// middleware registered is outermost: it sees the request first and the
+// response last.
+func (builder *RequestBuilder) chain() RoundTripper {
+	next := builder.roundTrip
+
+	all := make([]Middleware, 0, len(middlewares)+len(builder.middlewares))
+	all = append(all, middlewares...)
+	all = append(all, builder.middlewares...)
+
+	for i := len(all) - 1; i >= 0; i-- {
+		next = all[i](next)
+	}
+
+	return next
+}
+
+func (builder *RequestBuilder) roundTrip(ctx context.Context, request *http.Request) (*Response, error) {
+	request = request.WithContext(ctx)
+
+	response, err := builder.client.Do(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	out := &Response{Response: response, Body: body}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return out, errors.Wrapf(ErrUnexpectedResponseStatus, "got status %d", response.StatusCode)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned by RateLimiter in place of calling next when a
+// host's token bucket has no tokens available.
+var ErrRateLimited = errors.New("rate limit exceeded for this host")
+
+// RateLimiter is a per-host token-bucket rate limiter middleware: each host
+// gets its own bucket refilling at RequestsPerSecond, holding up to Burst
+// tokens.
+type RateLimiter struct {
+	requestsPerSecond rate.Limit
+	burst             int
+	mu                sync.Mutex
+	limiters          map[string]*rate.Limiter
+}
+
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		requestsPerSecond: rate.Limit(requestsPerSecond),
+		burst:             burst,
+		limiters:          make(map[string]*rate.Limiter),
+	}
+}
+
+func (limiter *RateLimiter) limiterFor(host string) *rate.Limiter {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	hostLimiter, ok := limiter.limiters[host]
+	if !ok {
+		hostLimiter = rate.NewLimiter(limiter.requestsPerSecond, limiter.burst)
+		limiter.limiters[host] = hostLimiter
+	}
+
+	return hostLimiter
+}
+
+// Middleware returns the limiter as a reqwest Middleware, so it can be
+// installed with reqwest.Use or builder.Use.
+func (limiter *RateLimiter) Middleware() Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return func(ctx context.Context, request *http.Request) (*Response, error) {
+			if !limiter.limiterFor(request.URL.Hostname()).Allow() {
+				return nil, errors.WithStack(ErrRateLimited)
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
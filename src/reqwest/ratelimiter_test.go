@@ -0,0 +1,65 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allows requests up to Burst, then rejects until the bucket refills", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(0, 2)
+
+		calls := 0
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			calls++
+			return &Response{}, nil
+		}
+
+		roundTrip := limiter.Middleware()(next)
+		request := newRequest(t)
+
+		_, err := roundTrip(context.Background(), request)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), request)
+		assert.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("tracks each host's bucket independently", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(0, 1)
+
+		next := func(ctx context.Context, request *http.Request) (*Response, error) {
+			return &Response{}, nil
+		}
+
+		roundTrip := limiter.Middleware()(next)
+
+		requestA, err := http.NewRequest(http.MethodGet, "http://a.example.com/", nil)
+		assert.NoError(t, err)
+
+		requestB, err := http.NewRequest(http.MethodGet, "http://b.example.com/", nil)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), requestA)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), requestB)
+		assert.NoError(t, err)
+
+		_, err = roundTrip(context.Background(), requestA)
+		assert.ErrorIs(t, err, ErrRateLimited)
+	})
+}
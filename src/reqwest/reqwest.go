@@ -8,17 +8,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
-)
-
-type Interceptor = func(*http.Request)
 
-var beforeEachInterceptors []Interceptor
-
-func BeforeEach(interceptor Interceptor) {
-	beforeEachInterceptors = append(beforeEachInterceptors, interceptor)
-}
+	"kata/src/tracing"
+)
 
 type ContextKey struct{ Value string }
 
@@ -26,19 +21,82 @@ var CorrelationIDContextKey = &ContextKey{Value: "correlation_id_context_key"}
 
 var CorrelationIDHeaderKey = "x-correlation-id"
 
+var TraceStateContextKey = &ContextKey{Value: "tracestate_context_key"}
+
+var TraceParentHeaderKey = "traceparent"
+
+var TraceStateHeaderKey = "tracestate"
+
 var ErrUnexpectedResponseStatus = errors.New("expected response status to be in the 200-299 range")
 
 type RequestBuilder struct {
-	client   *http.Client
-	ctx      context.Context
-	method   string
-	endpoint string
-	request  *http.Request
-	response *http.Response
-	query    url.Values
-	headers  http.Header
-	body     io.Reader
-	err      error
+	client      *http.Client
+	ctx         context.Context
+	method      string
+	endpoint    string
+	request     *http.Request
+	response    *http.Response
+	query       url.Values
+	headers     http.Header
+	body        io.Reader
+	getBody     func() io.Reader
+	retryPolicy *RetryPolicy
+	retrySafe   bool
+	timeout     time.Duration
+	deadline    time.Time
+	hedgePolicy *HedgePolicy
+	hedgeSafe   bool
+	middlewares []Middleware
+	err         error
+}
+
+// Timeout derives a child context with context.WithTimeout for this request
+// alone. When combined with Retry, the timeout is converted to an absolute
+// Deadline once so the whole retry sequence shares one budget instead of
+// each attempt getting a fresh d.
+func (builder *RequestBuilder) Timeout(d time.Duration) *RequestBuilder {
+	builder.timeout = d
+
+	return builder
+}
+
+// Deadline derives a child context with context.WithDeadline for this
+// request (and, with Retry, for the whole retry sequence).
+func (builder *RequestBuilder) Deadline(t time.Time) *RequestBuilder {
+	builder.deadline = t
+
+	return builder
+}
+
+// normalizeDeadline converts a relative Timeout into an absolute Deadline,
+// pinned to now. Call this once before looping over attempts (retry, hedge)
+// so every attempt derives its context from the same point in time.
+func (builder *RequestBuilder) normalizeDeadline() {
+	if builder.timeout > 0 && builder.deadline.IsZero() {
+		builder.deadline = time.Now().Add(builder.timeout)
+		builder.timeout = 0
+	}
+}
+
+// ensureSpan anchors one span on builder.ctx. Call this once before looping
+// over attempts (retry, hedge) so every attempt's Build derives its span
+// from this same anchor and they land in one trace, instead of each attempt
+// calling tracing.NewSpan on the original, spanless ctx and minting an
+// unrelated trace-id.
+func (builder *RequestBuilder) ensureSpan() {
+	builder.ctx = tracing.ContextWithSpan(builder.ctx, tracing.NewSpan(builder.ctx))
+}
+
+func (builder *RequestBuilder) deadlineContext() (context.Context, context.CancelFunc) {
+	if !builder.deadline.IsZero() {
+		return context.WithDeadline(builder.ctx, builder.deadline)
+	}
+
+	if builder.timeout > 0 {
+		return context.WithTimeout(builder.ctx, builder.timeout)
+	}
+
+	return builder.ctx, func() {}
 }
 
 func (builder *RequestBuilder) Header(key, value string) *RequestBuilder {
@@ -86,7 +144,13 @@ func (builder *RequestBuilder) Build() *ResponseBuilder {
 		return out
 	}
 
-	req = req.WithContext(builder.ctx)
+	ctx, cancel := builder.deadlineContext()
+	out.cancel = cancel
+
+	span := tracing.NewSpan(ctx)
+	ctx = tracing.ContextWithSpan(ctx, span)
+
+	req = req.WithContext(ctx)
 
 	if len(builder.headers) > 0 {
 		req.Header = builder.headers
@@ -96,14 +160,29 @@ func (builder *RequestBuilder) Build() *ResponseBuilder {
 		req.Header.Add(CorrelationIDHeaderKey, correlationID)
 	}
 
+	req.Header.Set(TraceParentHeaderKey, span.TraceParent())
+
+	if traceState, ok := builder.ctx.Value(TraceStateContextKey).(string); ok {
+		req.Header.Set(TraceStateHeaderKey, traceState)
+	}
+
 	req.URL.RawQuery = builder.query.Encode()
 
 	out.request = req
+	out.roundTripper = builder.chain()
 
 	return out
 }
 
 func (builder *RequestBuilder) Send() (Response, error) {
+	if builder.hedgePolicy != nil && builder.isHedgeable() {
+		return builder.sendWithHedge()
+	}
+
+	if builder.retryPolicy != nil && builder.isRetryable() {
+		return builder.sendWithRetry()
+	}
+
 	responseBuilder := builder.Build()
 
 	responseBuilder.makeRequest()
@@ -120,7 +199,21 @@ type ImpureRequestBuilder struct {
 }
 
 func (builder *ImpureRequestBuilder) Body(reader io.Reader) *ImpureRequestBuilder {
-	builder.body = reader
+	if reader == nil {
+		builder.body = nil
+		return builder
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		builder.err = errors.WithStack(err)
+		return builder
+	}
+
+	builder.body = bytes.NewReader(data)
+	builder.getBody = func() io.Reader {
+		return bytes.NewReader(data)
+	}
 
 	return builder
 }
@@ -131,6 +224,48 @@ func (builder *ImpureRequestBuilder) Header(key, value string) *ImpureRequestBui
 	return builder
 }
 
+func (builder *ImpureRequestBuilder) Retry(policy RetryPolicy) *ImpureRequestBuilder {
+	builder.RequestBuilder.Retry(policy)
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) RetrySafe() *ImpureRequestBuilder {
+	builder.RequestBuilder.RetrySafe()
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) Timeout(d time.Duration) *ImpureRequestBuilder {
+	builder.RequestBuilder.Timeout(d)
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) Deadline(t time.Time) *ImpureRequestBuilder {
+	builder.RequestBuilder.Deadline(t)
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) Hedge(policy HedgePolicy) *ImpureRequestBuilder {
+	builder.RequestBuilder.Hedge(policy)
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) HedgeSafe() *ImpureRequestBuilder {
+	builder.RequestBuilder.HedgeSafe()
+
+	return builder
+}
+
+func (builder *ImpureRequestBuilder) Use(mw ...Middleware) *ImpureRequestBuilder {
+	builder.RequestBuilder.Use(mw...)
+
+	return builder
+}
+
 func (builder *ImpureRequestBuilder) JSON(body interface{}) *ImpureRequestBuilder {
 	if builder.err != nil {
 		return builder
@@ -148,10 +283,12 @@ func (builder *ImpureRequestBuilder) JSON(body interface{}) *ImpureRequestBuilde
 }
 
 type ResponseBuilder struct {
-	client   *http.Client
-	request  *http.Request
-	response Response
-	err      error
+	client       *http.Client
+	request      *http.Request
+	response     Response
+	roundTripper RoundTripper
+	cancel       context.CancelFunc
+	err          error
 }
 
 type Response struct {
@@ -176,30 +313,21 @@ func (response *Response) Text() string {
 }
 
 func (builder *ResponseBuilder) makeRequest() {
+	if builder.cancel != nil {
+		defer builder.cancel()
+	}
+
 	if builder.err != nil {
 		return
 	}
 
-	for _, interceptor := range beforeEachInterceptors {
-		interceptor(builder.request)
+	response, err := builder.roundTripper(builder.request.Context(), builder.request)
+	if response != nil {
+		builder.response = *response
 	}
 
-	response, err := builder.client.Do(builder.request)
-
-	builder.response = Response{Response: response}
-
 	if err != nil {
-		builder.err = errors.WithStack(err)
-		return
-	}
-
-	body, _ := ioutil.ReadAll(response.Body)
-	builder.response.Body = body
-
-	response.Body.Close()
-
-	if response.StatusCode < 200 || response.StatusCode > 299 {
-		builder.err = errors.Wrapf(ErrUnexpectedResponseStatus, "got status %d", response.StatusCode)
+		builder.err = err
 	}
 }
 
@@ -249,6 +377,31 @@ func (builder *PureRequestBuilder) Header(key, value string) *PureRequestBuilder
 	return builder
 }
 
+func (builder *PureRequestBuilder) Retry(policy RetryPolicy) *PureRequestBuilder {
+	builder.RequestBuilder.Retry(policy)
+	return builder
+}
+
+func (builder *PureRequestBuilder) Timeout(d time.Duration) *PureRequestBuilder {
+	builder.RequestBuilder.Timeout(d)
+	return builder
+}
+
+func (builder *PureRequestBuilder) Deadline(t time.Time) *PureRequestBuilder {
+	builder.RequestBuilder.Deadline(t)
+	return builder
+}
+
+func (builder *PureRequestBuilder) Hedge(policy HedgePolicy) *PureRequestBuilder {
+	builder.RequestBuilder.Hedge(policy)
+	return builder
+}
+
+func (builder *PureRequestBuilder) Use(mw ...Middleware) *PureRequestBuilder {
+	builder.RequestBuilder.Use(mw...)
+	return builder
+}
+
 func GET(ctx context.Context, endpoint string) *PureRequestBuilder {
 	return &PureRequestBuilder{
 		RequestBuilder: RequestBuilder{
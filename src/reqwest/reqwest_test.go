@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IQ-tech/go-datagen"
 	"github.com/pkg/errors"
@@ -20,56 +22,87 @@ func randomEndpoint() string {
 	return fmt.Sprintf("http://localhost:5000/%s/", datagen.StringWithAlphabetic(10))
 }
 
-func Test_BeforeEach(t *testing.T) {
-	t.Parallel()
+func Test_Use(t *testing.T) {
+	t.Run("runs global middlewares outermost, then the builder's own, in registration order", func(t *testing.T) {
+		defer gock.Off()
 
-	t.Run("does not call interceptors if builder is in error state", func(t *testing.T) {
-		BeforeEach(func(_ *http.Request) {
-			panic("called")
-		})
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).
+			Get("").
+			Reply(200).
+			Body(strings.NewReader("hello world"))
+
+		var order []string
+
+		record := func(name string) Middleware {
+			return func(next RoundTripper) RoundTripper {
+				return func(ctx context.Context, request *http.Request) (*Response, error) {
+					order = append(order, name+":before")
+					response, err := next(ctx, request)
+					order = append(order, name+":after")
+					return response, err
+				}
+			}
+		}
 
-		responseBuilder := GET(context.Background(), randomEndpoint())
-		responseBuilder.err = errors.New("some error")
+		Use(record("global"))
+		defer func() { middlewares = nil }()
 
-		_, _ = responseBuilder.Send()
+		_, err := GET(context.Background(), endpoint).Use(record("builder")).Send()
 
-		beforeEachInterceptors = make([]Interceptor, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"global:before", "builder:before", "builder:after", "global:after"}, order)
 	})
 
-	t.Run("passes request to interceptors before request is sent", func(t *testing.T) {
-		defer gock.Off()
+	t.Run("a middleware that returns without calling next short-circuits the chain", func(t *testing.T) {
+		shortCircuit := errors.New("short circuited")
 
-		endpoints := []string{
-			"http://localhost:5000/test_before_each_1",
-			"http://localhost:5000/test_before_each_2",
-			"http://localhost:5000/test_before_each_3",
+		blocker := func(next RoundTripper) RoundTripper {
+			return func(ctx context.Context, request *http.Request) (*Response, error) {
+				return nil, shortCircuit
+			}
 		}
 
-		endpointsCalled := make([]string, 0, len(endpoints))
+		reached := false
 
-		BeforeEach(func(req *http.Request) {
-			endpointsCalled = append(endpointsCalled, req.URL.String())
-		})
+		_, err := GET(context.Background(), randomEndpoint()).
+			Use(blocker, func(next RoundTripper) RoundTripper {
+				return func(ctx context.Context, request *http.Request) (*Response, error) {
+					reached = true
+					return next(ctx, request)
+				}
+			}).
+			Send()
+
+		assert.Equal(t, shortCircuit, errors.Cause(err))
+		assert.False(t, reached)
+	})
 
-		for _, endpoint := range endpoints {
-			gock.New(endpoint).
-				Get("").
-				Reply(200).
-				Body(strings.NewReader("hello world"))
+	t.Run("a context derived by a middleware reaches the underlying client call", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
 
-			_, _ = GET(context.Background(), endpoint).Send()
+		gock.New(endpoint).Get("").Reply(200)
+
+		cancelCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		injectCanceledCtx := func(next RoundTripper) RoundTripper {
+			return func(_ context.Context, request *http.Request) (*Response, error) {
+				return next(cancelCtx, request)
+			}
 		}
 
-		assert.Equal(t, endpoints, endpointsCalled)
+		_, err := GET(context.Background(), endpoint).Use(injectCanceledCtx).Send()
+
+		assert.ErrorIs(t, err, context.Canceled)
 	})
 }
 
 func Test_CreatesRequestBuilder(t *testing.T) {
-	t.Parallel()
-
 	t.Run("GET", func(t *testing.T) {
-		t.Parallel()
-
 		ctx := context.Background()
 		endpoint := randomEndpoint()
 
@@ -85,8 +118,6 @@ func Test_CreatesRequestBuilder(t *testing.T) {
 	})
 
 	t.Run("POST", func(t *testing.T) {
-		t.Parallel()
-
 		ctx := context.Background()
 		endpoint := randomEndpoint()
 
@@ -102,8 +133,6 @@ func Test_CreatesRequestBuilder(t *testing.T) {
 	})
 
 	t.Run("PATCH", func(t *testing.T) {
-		t.Parallel()
-
 		ctx := context.Background()
 		endpoint := randomEndpoint()
 
@@ -119,8 +148,6 @@ func Test_CreatesRequestBuilder(t *testing.T) {
 	})
 
 	t.Run("PUT", func(t *testing.T) {
-		t.Parallel()
-
 		ctx := context.Background()
 		endpoint := randomEndpoint()
 
@@ -137,8 +164,6 @@ func Test_CreatesRequestBuilder(t *testing.T) {
 }
 
 func Test_PureRequestBuilder_Header(t *testing.T) {
-	t.Parallel()
-
 	t.Run("adds header to request", func(t *testing.T) {
 		request, err := GET(context.Background(), "https://api.github.com/users/poorlydefinedbehaviour/repos").
 			Header("key1", "value1").
@@ -153,13 +178,13 @@ func Test_PureRequestBuilder_Header(t *testing.T) {
 		}
 
 		assert.NoError(t, err)
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, request.Header.Get(TraceParentHeaderKey))
+		request.Header.Del(TraceParentHeaderKey)
 		assert.EqualValues(t, expected, request.Header)
 	})
 }
 
 func Test_PureRequestBuilder_Query(t *testing.T) {
-	t.Parallel()
-
 	t.Run("adds query string to request url", func(t *testing.T) {
 		request, err := GET(context.Background(), "https://api.github.com/users/poorlydefinedbehaviour/repos").
 			Query("key1", "value1").
@@ -177,11 +202,7 @@ func Test_PureRequestBuilder_Query(t *testing.T) {
 }
 
 func Test_PureRequestBuilder_Body(t *testing.T) {
-	t.Parallel()
-
 	t.Run("adds any io.Reader to request body", func(t *testing.T) {
-		t.Parallel()
-
 		payload := "hello world"
 
 		request, err := POST(context.Background(), "https://api.github.com/users/poorlydefinedbehaviour/repos").
@@ -199,8 +220,6 @@ func Test_PureRequestBuilder_Body(t *testing.T) {
 }
 
 func Test_ImpureRequestBuilder_Header(t *testing.T) {
-	t.Parallel()
-
 	t.Run("adds header to request", func(t *testing.T) {
 		request, err := POST(context.Background(), "https://api.github.com/users/poorlydefinedbehaviour/repos").
 			Header("key1", "value1").
@@ -214,19 +233,15 @@ func Test_ImpureRequestBuilder_Header(t *testing.T) {
 			"key3": {"VALUE3"},
 		}
 
-		fmt.Printf("\n\naaaaaaa request %+v\n\n", request)
-
 		assert.NoError(t, err)
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, request.Header.Get(TraceParentHeaderKey))
+		request.Header.Del(TraceParentHeaderKey)
 		assert.EqualValues(t, expected, request.Header)
 	})
 }
 
 func Test_ImpureRequestBuilder_JSON(t *testing.T) {
-	t.Parallel()
-
 	t.Run("marshals json and adds it to request body", func(t *testing.T) {
-		t.Parallel()
-
 		payload := map[string]string{
 			"hello": "world",
 		}
@@ -250,14 +265,8 @@ func Test_ImpureRequestBuilder_JSON(t *testing.T) {
 }
 
 func Test_ResponseBuilder_makeRequest(t *testing.T) {
-	t.Parallel()
-
 	t.Run("when response status is not in the 200-299 range", func(t *testing.T) {
-		t.Parallel()
-
 		t.Run("returns custom error", func(t *testing.T) {
-			t.Parallel()
-
 			defer gock.Off()
 
 			for _, status := range []int{103, 300} {
@@ -275,17 +284,12 @@ func Test_ResponseBuilder_makeRequest(t *testing.T) {
 		})
 
 		t.Run("consumes response body and closes it", func(t *testing.T) {
-			t.Parallel()
 		})
 	})
 }
 
 func Test_ResponseBuilder_Build(t *testing.T) {
-	t.Parallel()
-
 	t.Run("if context has a correlation id, adds it to the request", func(t *testing.T) {
-		t.Parallel()
-
 		requestID := "03823a30-5bf8-4cd9-ac53-d12d18ab6d3d"
 
 		ctx := context.WithValue(context.Background(), CorrelationIDContextKey, requestID)
@@ -298,26 +302,290 @@ func Test_ResponseBuilder_Build(t *testing.T) {
 	})
 }
 
-func Test_ResponseBuilder_Request(t *testing.T) {
-	t.Parallel()
+func Test_RetryPolicy_backoff(t *testing.T) {
+	t.Run("doubles the delay per attempt and caps it at MaxDelay", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 1 * time.Second}
 
-	t.Run("returns the http request", func(t *testing.T) {
-		t.Parallel()
+		assert.Equal(t, 100*time.Millisecond, policy.backoff(0))
+		assert.Equal(t, 200*time.Millisecond, policy.backoff(1))
+		assert.Equal(t, 400*time.Millisecond, policy.backoff(2))
+		assert.Equal(t, 1*time.Second, policy.backoff(10))
+	})
+}
+
+func Test_DefaultRetryable(t *testing.T) {
+	t.Run("retries network errors", func(t *testing.T) {
+		assert.True(t, DefaultRetryable(nil, errors.New("boom")))
+	})
+
+	t.Run("retries 5xx and 429 responses", func(t *testing.T) {
+		assert.True(t, DefaultRetryable(&http.Response{StatusCode: 500}, nil))
+		assert.True(t, DefaultRetryable(&http.Response{StatusCode: 429}, nil))
+	})
+
+	t.Run("does not retry a successful response", func(t *testing.T) {
+		assert.False(t, DefaultRetryable(&http.Response{StatusCode: 200}, nil))
+	})
+}
+
+func Test_RequestBuilder_Send_retry(t *testing.T) {
+	t.Run("retries a GET until it succeeds", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Get("").Times(1).Reply(503)
+		gock.New(endpoint).Get("").Times(1).Reply(200).Body(strings.NewReader("hello world"))
+
+		response, err := GET(context.Background(), endpoint).
+			Retry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hello world"), response.Bytes())
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("does not retry a POST without an idempotency key or RetrySafe", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Post("").Times(1).Reply(503)
+
+		_, err := POST(context.Background(), endpoint).
+			Retry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}).
+			Send()
+
+		assert.Error(t, err)
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("retries a POST when RetrySafe is set", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Post("").Times(1).Reply(503)
+		gock.New(endpoint).Post("").Times(1).Reply(200).Body(strings.NewReader("ok"))
+
+		response, err := POST(context.Background(), endpoint).
+			RetrySafe().
+			Retry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ok"), response.Bytes())
+	})
+
+	t.Run("retries a POST when an Idempotency-Key header is set regardless of its casing", func(t *testing.T) {
+		defer gock.Off()
 
-		ctx := context.Background()
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Post("").Times(1).Reply(503)
+		gock.New(endpoint).Post("").Times(1).Reply(200).Body(strings.NewReader("ok"))
+
+		response, err := POST(context.Background(), endpoint).
+			Header("idempotency-key", "abc").
+			Retry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ok"), response.Bytes())
+	})
+
+	t.Run("sends once and does not panic when MaxAttempts is zero", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Get("").Times(1).Reply(200).Body(strings.NewReader("ok"))
+
+		response, err := GET(context.Background(), endpoint).
+			Retry(RetryPolicy{}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ok"), response.Bytes())
+		assert.True(t, gock.IsDone())
+	})
+
+	t.Run("every attempt shares one trace", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		var traceparents []string
+
+		gock.New(endpoint).Post("").Times(1).Map(func(r *http.Request) *http.Request {
+			traceparents = append(traceparents, r.Header.Get(TraceParentHeaderKey))
+			return r
+		}).Reply(503)
+
+		gock.New(endpoint).Post("").Times(1).Map(func(r *http.Request) *http.Request {
+			traceparents = append(traceparents, r.Header.Get(TraceParentHeaderKey))
+			return r
+		}).Reply(200).Body(strings.NewReader("ok"))
+
+		_, err := POST(context.Background(), endpoint).
+			RetrySafe().
+			Retry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Len(t, traceparents, 2)
+
+		traceID := func(traceparent string) string {
+			parts := strings.Split(traceparent, "-")
+			return parts[1]
+		}
+
+		assert.Equal(t, traceID(traceparents[0]), traceID(traceparents[1]))
+		assert.NotEqual(t, traceparents[0], traceparents[1])
+	})
+}
+
+func Test_RequestBuilder_Timeout(t *testing.T) {
+	t.Run("aborts the request once the timeout elapses", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Get("").Reply(200).Delay(50 * time.Millisecond)
+
+		_, err := GET(context.Background(), endpoint).
+			Timeout(5 * time.Millisecond).
+			Send()
+
+		assert.Error(t, err)
+	})
+}
+
+func Test_RequestBuilder_Deadline(t *testing.T) {
+	t.Run("aborts the request once the deadline has already passed", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Get("").Reply(200)
+
+		_, err := GET(context.Background(), endpoint).
+			Deadline(time.Now().Add(-time.Second)).
+			Send()
+
+		assert.Error(t, err)
+	})
+}
+
+func Test_RequestBuilder_Hedge(t *testing.T) {
+	t.Run("returns whichever duplicate completes first", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Get("").Times(1).Reply(200).Delay(50 * time.Millisecond).Body(strings.NewReader("slow"))
+		gock.New(endpoint).Get("").Times(1).Reply(200).Body(strings.NewReader("fast"))
+
+		response, err := GET(context.Background(), endpoint).
+			Hedge(HedgePolicy{After: 5 * time.Millisecond, MaxExtra: 1}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("fast"), response.Bytes())
+	})
+
+	t.Run("does not hedge a POST without HedgeSafe", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		gock.New(endpoint).Post("").Times(1).Reply(200).Body(strings.NewReader("only call"))
+
+		response, err := POST(context.Background(), endpoint).
+			Hedge(HedgePolicy{After: time.Millisecond, MaxExtra: 1}).
+			Send()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("only call"), response.Bytes())
+	})
+
+	t.Run("every duplicate shares one trace", func(t *testing.T) {
+		defer gock.Off()
+
+		endpoint := randomEndpoint()
+
+		var mu sync.Mutex
+		var traceparents []string
+
+		capture := func(r *http.Request) *http.Request {
+			mu.Lock()
+			traceparents = append(traceparents, r.Header.Get(TraceParentHeaderKey))
+			mu.Unlock()
+			return r
+		}
+
+		gock.New(endpoint).Get("").Times(1).Map(capture).Reply(200).Delay(50 * time.Millisecond).Body(strings.NewReader("slow"))
+		gock.New(endpoint).Get("").Times(1).Map(capture).Reply(200).Body(strings.NewReader("fast"))
+
+		_, err := GET(context.Background(), endpoint).
+			Hedge(HedgePolicy{After: 5 * time.Millisecond, MaxExtra: 1}).
+			Send()
+
+		assert.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Len(t, traceparents, 2)
+
+		traceID := func(traceparent string) string {
+			parts := strings.Split(traceparent, "-")
+			return parts[1]
+		}
+
+		assert.Equal(t, traceID(traceparents[0]), traceID(traceparents[1]))
+		assert.NotEqual(t, traceparents[0], traceparents[1])
+	})
+}
+
+func Test_ResponseBuilder_Build_traceparent(t *testing.T) {
+	t.Run("sets a traceparent header with a fresh trace id when context has no span", func(t *testing.T) {
+		request, err := GET(context.Background(), randomEndpoint()).Request()
+
+		assert.NoError(t, err)
+
+		traceparent := request.Header.Get("traceparent")
+
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, traceparent)
+	})
+
+	t.Run("forwards tracestate when present in the context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), TraceStateContextKey, "congo=t61rcWkgMzE")
+
+		request, err := GET(ctx, randomEndpoint()).Request()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "congo=t61rcWkgMzE", request.Header.Get("tracestate"))
+	})
+}
+
+func Test_ResponseBuilder_Request(t *testing.T) {
+	t.Run("returns the http request", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), CorrelationIDContextKey, "correlation-id")
 		endpoint := randomEndpoint()
 
 		request, err := GET(ctx, endpoint).Request()
 
 		assert.NoError(t, err)
-		assert.Equal(t, ctx, request.Context())
+		// Build() derives the request's context from ctx to attach a span, so
+		// request.Context() is a child of ctx rather than ctx itself.
+		assert.Equal(t, "correlation-id", request.Context().Value(CorrelationIDContextKey))
 		assert.Equal(t, http.MethodGet, request.Method)
 		assert.Equal(t, endpoint, request.URL.String())
 	})
 
 	t.Run("returns error if an error happened in the process", func(t *testing.T) {
-		t.Parallel()
-
 		builder := GET(context.Background(), randomEndpoint())
 
 		expectedErr := errors.New("some error")
@@ -331,11 +599,7 @@ func Test_ResponseBuilder_Request(t *testing.T) {
 }
 
 func Test_Response_Text(t *testing.T) {
-	t.Parallel()
-
 	t.Run("returns response body as text", func(t *testing.T) {
-		t.Parallel()
-
 		defer gock.Off()
 
 		endpoint := randomEndpoint()
@@ -354,11 +618,7 @@ func Test_Response_Text(t *testing.T) {
 }
 
 func Test_Response_Bytes(t *testing.T) {
-	t.Parallel()
-
 	t.Run("returns response body as []byte", func(t *testing.T) {
-		t.Parallel()
-
 		defer gock.Off()
 
 		endpoint := randomEndpoint()
@@ -377,11 +637,7 @@ func Test_Response_Bytes(t *testing.T) {
 }
 
 func Test_Response_JSON(t *testing.T) {
-	t.Parallel()
-
 	t.Run("returns error if json can be parsed", func(t *testing.T) {
-		t.Parallel()
-
 		defer gock.Off()
 
 		endpoint := randomEndpoint()
@@ -399,8 +655,6 @@ func Test_Response_JSON(t *testing.T) {
 	})
 
 	t.Run("returns response body as json", func(t *testing.T) {
-		t.Parallel()
-
 		defer gock.Off()
 
 		endpoint := randomEndpoint()
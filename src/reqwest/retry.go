@@ -0,0 +1,239 @@
+package reqwest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"kata/src/logger"
+)
+
+// JitterStrategy spreads retry delays out so that many clients backing off
+// at once don't all retry in lockstep.
+type JitterStrategy func(backoff time.Duration) time.Duration
+
+// FullJitter picks a random delay in [0, backoff].
+var FullJitter JitterStrategy = func(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// EqualJitter picks a random delay in [backoff/2, backoff].
+var EqualJitter JitterStrategy = func(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// RetryOn decides, given the response and/or error a request attempt
+// produced, whether another attempt should be made.
+type RetryOn func(response *http.Response, err error) bool
+
+// DefaultRetryable retries network errors, 5xx and 429 responses.
+func DefaultRetryable(response *http.Response, err error) bool {
+	// A non-2xx response also comes with an error (ErrUnexpectedResponseStatus),
+	// so the status decides whenever there is a response.
+	if response != nil {
+		return response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+	}
+
+	return err != nil
+}
+
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts (including the first,
+	// non-retry one). Values below 1, including the zero value, are treated
+	// as 1: send once, don't retry.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      JitterStrategy
+	RetryOn     RetryOn
+}
+
+func (policy RetryPolicy) retryOn() RetryOn {
+	if policy.RetryOn != nil {
+		return policy.RetryOn
+	}
+
+	return DefaultRetryable
+}
+
+// backoff computes min(MaxDelay, BaseDelay*2^attempt) and applies Jitter,
+// where attempt is the number of retries already made (0 for the delay
+// before the first retry).
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := policy.BaseDelay
+
+	for i := 0; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if policy.Jitter != nil {
+		delay = policy.Jitter(delay)
+	}
+
+	return delay
+}
+
+// retryAfter parses the standard `Retry-After` header (either a number of
+// seconds or an HTTP-date), returning ok=false when it's absent or invalid.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+
+	return 0, false
+}
+
+// Retry attaches a retry policy to the request. Idempotent methods
+// (GET/PUT/DELETE/HEAD/OPTIONS) retry as configured; POST/PATCH only retry
+// when RetrySafe was called or an `Idempotency-Key` header is present.
+func (builder *RequestBuilder) Retry(policy RetryPolicy) *RequestBuilder {
+	builder.retryPolicy = &policy
+
+	return builder
+}
+
+// RetrySafe opts a non-idempotent request (POST/PATCH) into the retry
+// policy set via Retry.
+func (builder *RequestBuilder) RetrySafe() *RequestBuilder {
+	builder.retrySafe = true
+
+	return builder
+}
+
+// defaultIdempotentMethod reports whether method is safe to repeat without
+// an explicit opt-in: shared by retries and hedging.
+func defaultIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func (builder *RequestBuilder) isRetryable() bool {
+	if defaultIdempotentMethod(builder.method) {
+		return true
+	}
+
+	return builder.retrySafe || headerValue(builder.headers, "Idempotency-Key") != ""
+}
+
+// headerValue looks up key case-insensitively. builder.headers stores
+// whatever casing the caller passed to Header() (it isn't canonicalized
+// like http.Header normally is), so http.Header.Get, which canonicalizes
+// only the key it's searching for, would miss e.g. "idempotency-key".
+func headerValue(headers http.Header, key string) string {
+	for name, values := range headers {
+		if strings.EqualFold(name, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+func (builder *RequestBuilder) sleep(delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-builder.ctx.Done():
+		return builder.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (builder *RequestBuilder) sendWithRetry() (Response, error) {
+	builder.normalizeDeadline()
+	builder.ensureSpan()
+
+	policy := builder.retryPolicy
+	retryOn := policy.retryOn()
+
+	log := logger.New()
+	if correlationID, ok := builder.ctx.Value(CorrelationIDContextKey).(string); ok {
+		log.Prefix(correlationID)
+	}
+	tracedLog := log.Context(builder.ctx)
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var responseBuilder *ResponseBuilder
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if builder.getBody != nil {
+				builder.body = builder.getBody()
+			}
+
+			delay, ok := retryAfter(responseBuilder.response.Response)
+			if !ok {
+				delay = policy.backoff(attempt - 1)
+			}
+
+			if err := builder.sleep(delay); err != nil {
+				return responseBuilder.response, errors.WithStack(err)
+			}
+
+			tracedLog.Info(fmt.Sprintf("retrying %s %s (attempt %d/%d)", builder.method, builder.endpoint, attempt+1, maxAttempts))
+		}
+
+		responseBuilder = builder.Build()
+		responseBuilder.makeRequest()
+
+		if !retryOn(responseBuilder.response.Response, responseBuilder.err) {
+			break
+		}
+	}
+
+	if responseBuilder.err != nil {
+		return responseBuilder.response, errors.WithStack(responseBuilder.err)
+	}
+
+	return responseBuilder.response, nil
+}
@@ -0,0 +1,10 @@
+package httpmw
+
+import "net/http"
+
+// Chi returns a chi-compatible middleware. chi middlewares share the
+// standard net/http `func(http.Handler) http.Handler` signature, so this is
+// Middleware itself, exported under the name chi.Router.Use callers expect.
+func Chi() func(http.Handler) http.Handler {
+	return Middleware
+}
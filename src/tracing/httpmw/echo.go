@@ -0,0 +1,21 @@
+package httpmw
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"kata/src/reqwest"
+)
+
+// Echo is the echo adapter: echo.Echo.Use(httpmw.Echo).
+func Echo(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, correlationID, span := newRequestContext(c.Request())
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		c.Response().Header().Set(reqwest.CorrelationIDHeaderKey, correlationID)
+		c.Response().Header().Set(reqwest.TraceParentHeaderKey, span.TraceParent())
+
+		return next(c)
+	}
+}
@@ -0,0 +1,56 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"kata/src/reqwest"
+)
+
+func Test_Echo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generates correlation id and traceparent when request has none", func(t *testing.T) {
+		t.Parallel()
+
+		e := echo.New()
+
+		var gotCorrelationID string
+
+		handler := Echo(func(c echo.Context) error {
+			gotCorrelationID, _ = c.Request().Context().Value(reqwest.CorrelationIDContextKey).(string)
+			return nil
+		})
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		assert.NoError(t, handler(e.NewContext(request, recorder)))
+
+		assert.NotEmpty(t, gotCorrelationID)
+		assert.Equal(t, gotCorrelationID, recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, recorder.Header().Get(reqwest.TraceParentHeaderKey))
+	})
+
+	t.Run("reuses the inbound correlation id and traceparent's trace id", func(t *testing.T) {
+		t.Parallel()
+
+		e := echo.New()
+
+		handler := Echo(func(c echo.Context) error { return nil })
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(reqwest.CorrelationIDHeaderKey, "some-correlation-id")
+		request.Header.Set(reqwest.TraceParentHeaderKey, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+		assert.NoError(t, handler(e.NewContext(request, recorder)))
+
+		assert.Equal(t, "some-correlation-id", recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Contains(t, recorder.Header().Get(reqwest.TraceParentHeaderKey), "0af7651916cd43dd8448eb211c80319c")
+	})
+}
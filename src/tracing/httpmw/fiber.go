@@ -0,0 +1,44 @@
+package httpmw
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"kata/src/logger"
+	"kata/src/reqwest"
+	"kata/src/tracing"
+)
+
+// Fiber is the fiber adapter: app.Use(httpmw.Fiber). fiber.Ctx isn't backed
+// by a net/http request, so it reads/writes headers through fiber's own API
+// instead of newRequestContext, and stores the context on the fiber.Ctx via
+// SetUserContext rather than wrapping an *http.Request.
+func Fiber(c *fiber.Ctx) error {
+	correlationID := c.Get(reqwest.CorrelationIDHeaderKey)
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
+	span, ok := parseTraceParent(c.Get(reqwest.TraceParentHeaderKey))
+	if ok {
+		span = span.NewChild()
+	} else {
+		span = tracing.NewSpan(c.UserContext())
+	}
+
+	ctx := context.WithValue(c.UserContext(), reqwest.CorrelationIDContextKey, correlationID)
+	ctx = tracing.ContextWithSpan(ctx, span)
+
+	log := logger.New()
+	log.Prefix(correlationID)
+	ctx = context.WithValue(ctx, LoggerContextKey, &log)
+
+	c.SetUserContext(ctx)
+
+	c.Set(reqwest.CorrelationIDHeaderKey, correlationID)
+	c.Set(reqwest.TraceParentHeaderKey, span.TraceParent())
+
+	return c.Next()
+}
@@ -0,0 +1,56 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"kata/src/reqwest"
+)
+
+func Test_Fiber(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generates correlation id and traceparent when request has none", func(t *testing.T) {
+		t.Parallel()
+
+		var gotCorrelationID string
+
+		app := fiber.New()
+		app.Use(Fiber)
+		app.Get("/", func(c *fiber.Ctx) error {
+			gotCorrelationID, _ = c.UserContext().Value(reqwest.CorrelationIDContextKey).(string)
+			return nil
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		response, err := app.Test(request)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, gotCorrelationID)
+		assert.Equal(t, gotCorrelationID, response.Header.Get(reqwest.CorrelationIDHeaderKey))
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, response.Header.Get(reqwest.TraceParentHeaderKey))
+	})
+
+	t.Run("reuses the inbound correlation id and traceparent's trace id", func(t *testing.T) {
+		t.Parallel()
+
+		app := fiber.New()
+		app.Use(Fiber)
+		app.Get("/", func(c *fiber.Ctx) error { return nil })
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(reqwest.CorrelationIDHeaderKey, "some-correlation-id")
+		request.Header.Set(reqwest.TraceParentHeaderKey, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+		response, err := app.Test(request)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "some-correlation-id", response.Header.Get(reqwest.CorrelationIDHeaderKey))
+		assert.Contains(t, response.Header.Get(reqwest.TraceParentHeaderKey), "0af7651916cd43dd8448eb211c80319c")
+	})
+}
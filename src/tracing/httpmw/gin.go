@@ -0,0 +1,21 @@
+package httpmw
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"kata/src/reqwest"
+)
+
+// Gin is the gin adapter: gin.Engine.Use(httpmw.Gin()).
+func Gin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, correlationID, span := newRequestContext(c.Request)
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Header(reqwest.CorrelationIDHeaderKey, correlationID)
+		c.Header(reqwest.TraceParentHeaderKey, span.TraceParent())
+
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kata/src/reqwest"
+)
+
+func Test_Gin(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("generates correlation id and traceparent when request has none", func(t *testing.T) {
+		t.Parallel()
+
+		var gotCorrelationID string
+
+		engine := gin.New()
+		engine.Use(Gin())
+		engine.GET("/", func(c *gin.Context) {
+			gotCorrelationID, _ = c.Request.Context().Value(reqwest.CorrelationIDContextKey).(string)
+		})
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		engine.ServeHTTP(recorder, request)
+
+		assert.NotEmpty(t, gotCorrelationID)
+		assert.Equal(t, gotCorrelationID, recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, recorder.Header().Get(reqwest.TraceParentHeaderKey))
+	})
+
+	t.Run("reuses the inbound correlation id and traceparent's trace id", func(t *testing.T) {
+		t.Parallel()
+
+		engine := gin.New()
+		engine.Use(Gin())
+		engine.GET("/", func(c *gin.Context) {})
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(reqwest.CorrelationIDHeaderKey, "some-correlation-id")
+		request.Header.Set(reqwest.TraceParentHeaderKey, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+		engine.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "some-correlation-id", recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Contains(t, recorder.Header().Get(reqwest.TraceParentHeaderKey), "0af7651916cd43dd8448eb211c80319c")
+	})
+}
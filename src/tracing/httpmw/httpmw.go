@@ -0,0 +1,100 @@
+// Package httpmw terminates and originates correlation/trace context at the
+// edge of a server: it extracts `x-correlation-id` and `traceparent` from
+// inbound requests (generating them when missing), injects them into the
+// request's context.Context, sets them back on the response, and wires up a
+// per-request logger so handlers can just call reqwest.GET(r.Context(), ...)
+// and have propagation happen automatically.
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"kata/src/logger"
+	"kata/src/reqwest"
+	"kata/src/tracing"
+)
+
+type loggerContextKey struct{}
+
+var LoggerContextKey = &loggerContextKey{}
+
+// LoggerFromContext returns the per-request logger injected by this package's
+// middlewares, or a fresh, unprefixed logger if none is present.
+func LoggerFromContext(ctx context.Context) logger.T {
+	if log, ok := ctx.Value(LoggerContextKey).(logger.T); ok {
+		return log
+	}
+
+	log := logger.New()
+
+	return &log
+}
+
+func extractOrGenerateCorrelationID(r *http.Request) string {
+	if id := r.Header.Get(reqwest.CorrelationIDHeaderKey); id != "" {
+		return id
+	}
+
+	return uuid.New().String()
+}
+
+func parseTraceParent(value string) (tracing.Span, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 {
+		return tracing.Span{}, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if version != "00" || len(traceID) != 32 || len(spanID) != 16 {
+		return tracing.Span{}, false
+	}
+
+	flagByte, err := strconv.ParseUint(flags, 16, 8)
+	if err != nil {
+		return tracing.Span{}, false
+	}
+
+	return tracing.Span{TraceID: traceID, SpanID: spanID, Flags: byte(flagByte)}, true
+}
+
+func extractOrGenerateSpan(r *http.Request) tracing.Span {
+	if parent, ok := parseTraceParent(r.Header.Get(reqwest.TraceParentHeaderKey)); ok {
+		return parent.NewChild()
+	}
+
+	return tracing.NewSpan(r.Context())
+}
+
+// newRequestContext builds the context, correlation id, and span a single
+// inbound request should carry for the rest of its lifetime.
+func newRequestContext(r *http.Request) (context.Context, string, tracing.Span) {
+	correlationID := extractOrGenerateCorrelationID(r)
+	span := extractOrGenerateSpan(r)
+
+	ctx := context.WithValue(r.Context(), reqwest.CorrelationIDContextKey, correlationID)
+	ctx = tracing.ContextWithSpan(ctx, span)
+
+	log := logger.New()
+	log.Prefix(correlationID)
+	ctx = context.WithValue(ctx, LoggerContextKey, &log)
+
+	return ctx, correlationID, span
+}
+
+// Middleware is the net/http adapter: it terminates/originates correlation
+// and trace context and hands the enriched request to next.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, correlationID, span := newRequestContext(r)
+
+		w.Header().Set(reqwest.CorrelationIDHeaderKey, correlationID)
+		w.Header().Set(reqwest.TraceParentHeaderKey, span.TraceParent())
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,71 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kata/src/reqwest"
+)
+
+func Test_Middleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generates correlation id and traceparent when request has none", func(t *testing.T) {
+		t.Parallel()
+
+		var gotCorrelationID string
+
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotCorrelationID, _ = r.Context().Value(reqwest.CorrelationIDContextKey).(string)
+		}))
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.NotEmpty(t, gotCorrelationID)
+		assert.Equal(t, gotCorrelationID, recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`, recorder.Header().Get(reqwest.TraceParentHeaderKey))
+	})
+
+	t.Run("reuses the inbound correlation id and traceparent's trace id", func(t *testing.T) {
+		t.Parallel()
+
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(reqwest.CorrelationIDHeaderKey, "some-correlation-id")
+		request.Header.Set(reqwest.TraceParentHeaderKey, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "some-correlation-id", recorder.Header().Get(reqwest.CorrelationIDHeaderKey))
+		assert.Contains(t, recorder.Header().Get(reqwest.TraceParentHeaderKey), "0af7651916cd43dd8448eb211c80319c")
+	})
+}
+
+func Test_RequestLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recovers panics and responds 500", func(t *testing.T) {
+		t.Parallel()
+
+		handler := Middleware(RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		assert.NotPanics(t, func() {
+			handler.ServeHTTP(recorder, request)
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
@@ -0,0 +1,54 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	n, err := recorder.ResponseWriter.Write(data)
+	recorder.bytesWritten += n
+
+	return n, err
+}
+
+// RequestLogger logs method, path, status, duration and bytes-in/out for
+// every request using the correlation-id-scoped logger Middleware put in the
+// request's context, and recovers panics, logging the stack trace tagged
+// with the correlation id before responding 500.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := LoggerFromContext(r.Context())
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		start := time.Now()
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Error(fmt.Sprintf("panic: %v\n%s", recovered, debug.Stack()))
+				recorder.WriteHeader(http.StatusInternalServerError)
+			}
+
+			log.Info(fmt.Sprintf(
+				"%s %s %d %dms in=%d out=%d",
+				r.Method, r.URL.Path, recorder.status, time.Since(start).Milliseconds(), r.ContentLength, recorder.bytesWritten,
+			))
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
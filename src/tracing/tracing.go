@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Span is a single unit of work inside a distributed trace. TraceID and
+// SpanID follow the W3C Trace Context sizes (16 and 8 bytes respectively),
+// hex-encoded.
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Flags    byte
+}
+
+type ContextKey struct{ Value string }
+
+var SpanContextKey = &ContextKey{Value: "span_context_key"}
+
+const (
+	traceIDSize = 16
+	spanIDSize  = 8
+)
+
+func randomHexID(size int) string {
+	buf := make([]byte, size)
+
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+func NewTraceID() string {
+	return randomHexID(traceIDSize)
+}
+
+func NewSpanID() string {
+	return randomHexID(spanIDSize)
+}
+
+// NewChild returns a span that continues span's trace: same trace-id, a
+// fresh span-id, and parent-id set to span's own span-id. If span is the
+// zero value (no trace in progress yet), NewChild starts a new trace instead.
+func (span Span) NewChild() Span {
+	if span.TraceID == "" {
+		return Span{TraceID: NewTraceID(), SpanID: NewSpanID(), Flags: 0x01}
+	}
+
+	return Span{TraceID: span.TraceID, SpanID: NewSpanID(), ParentID: span.SpanID, Flags: span.Flags}
+}
+
+// TraceParent renders span as a W3C `traceparent` header value:
+// 00-<trace-id>-<span-id>-<flags>
+func (span Span) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", span.TraceID, span.SpanID, span.Flags)
+}
+
+// NewSpan starts a child of whatever span ctx carries, or a new root span
+// (with a fresh trace-id) if ctx carries none.
+func NewSpan(ctx context.Context) Span {
+	parent, _ := SpanFromContext(ctx)
+
+	return parent.NewChild()
+}
+
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(SpanContextKey).(Span)
+
+	return span, ok
+}
+
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, SpanContextKey, span)
+}
+
+// FinishedSpan is a Span that has completed, with enough timing information
+// to be shipped to a tracing backend.
+type FinishedSpan struct {
+	Span
+	Name      string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (span FinishedSpan) Duration() time.Duration {
+	return span.EndTime.Sub(span.StartTime)
+}
+
+// SpanExporter ships finished spans to a tracing backend (OTLP, Jaeger,
+// Zipkin, ...). Implementations should not block the caller for long;
+// exporters that talk to the network should buffer/batch internally.
+type SpanExporter interface {
+	Export(span FinishedSpan)
+}
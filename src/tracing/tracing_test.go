@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewSpan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("starts a root span when context has none", func(t *testing.T) {
+		t.Parallel()
+
+		span := NewSpan(context.Background())
+
+		assert.Len(t, span.TraceID, 32)
+		assert.Len(t, span.SpanID, 16)
+		assert.Empty(t, span.ParentID)
+	})
+
+	t.Run("starts a child span that keeps the parent's trace id", func(t *testing.T) {
+		t.Parallel()
+
+		parent := NewSpan(context.Background())
+		ctx := ContextWithSpan(context.Background(), parent)
+
+		child := NewSpan(ctx)
+
+		assert.Equal(t, parent.TraceID, child.TraceID)
+		assert.Equal(t, parent.SpanID, child.ParentID)
+		assert.NotEqual(t, parent.SpanID, child.SpanID)
+	})
+}
+
+func Test_Span_TraceParent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renders the w3c traceparent format", func(t *testing.T) {
+		t.Parallel()
+
+		span := Span{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", Flags: 0x01}
+
+		assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", span.TraceParent())
+	})
+}